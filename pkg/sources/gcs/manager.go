@@ -0,0 +1,413 @@
+package gcs
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// objectManager is the interface Source drives enumeration through. It's satisfied by
+// *gcsManager; tests substitute a fake implementation instead of talking to real GCS.
+type objectManager interface {
+	listObjects(ctx context.Context) (chan interface{}, error)
+	attributes(ctx context.Context) (*attributes, error)
+}
+
+// gcsManager builds and drives the underlying storage.Client used to enumerate buckets and
+// read objects. It's deliberately dumb about *how* it's authenticated or transported:
+// gcsManagerOptions mutate its unexported fields, and newGCSManager defers the actual
+// client construction until every option has run, so options that affect the same
+// underlying client (auth, transport, pooling) compose instead of racing to build and
+// assign their own client.
+type gcsManager struct {
+	projectID string
+
+	concurrency   int
+	maxObjectSize int64
+
+	// chunkSize is the ranged-read window size streamObject uses for large objects, set via
+	// withChunkSize; Source.Init copies it onto Source.chunkSize so the manager stays the one
+	// place that translates sourcespb.GCS.ChunkSize into a configured value.
+	chunkSize int64
+
+	includeBuckets []string
+	excludeBuckets []string
+	includeObjects []string
+	excludeObjects []string
+
+	ctx           context.Context
+	clientOptions []option.ClientOption
+	useGRPC       bool
+
+	client *storage.Client
+}
+
+// gcsManagerOption mutates a gcsManager under construction. Options that need to affect
+// client construction (auth, transport) append to clientOptions and set ctx rather than
+// building a client of their own; newGCSManager builds the one real client after every
+// option has run. It returns an error so options that can fail while building (e.g.
+// marshalling a credential) surface that failure instead of being silently swallowed.
+type gcsManagerOption func(*gcsManager) error
+
+// newGCSManager applies opts to a gcsManager and builds its storage client once, after every
+// option has had a chance to contribute auth and transport options.
+func newGCSManager(projectID string, opts ...gcsManagerOption) (*gcsManager, error) {
+	m := &gcsManager{projectID: projectID}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.buildClient(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *gcsManager) buildClient() error {
+	if m.ctx == nil {
+		return fmt.Errorf("GCS manager has no authentication configured, cannot build storage client")
+	}
+
+	var (
+		client *storage.Client
+		err    error
+	)
+	if m.useGRPC {
+		client, err = storage.NewGRPCClient(m.ctx, m.clientOptions...)
+	} else {
+		client, err = storage.NewClient(m.ctx, m.clientOptions...)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating GCS storage client: %w", err)
+	}
+
+	m.client = client
+	return nil
+}
+
+func withConcurrency(concurrency int) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.concurrency = concurrency
+		return nil
+	}
+}
+
+func withMaxObjectSize(size int64) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.maxObjectSize = size
+		return nil
+	}
+}
+
+func withIncludeBuckets(buckets []string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.includeBuckets = buckets
+		return nil
+	}
+}
+
+func withExcludeBuckets(buckets []string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.excludeBuckets = buckets
+		return nil
+	}
+}
+
+func withIncludeObjects(objects []string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.includeObjects = objects
+		return nil
+	}
+}
+
+func withExcludeObjects(objects []string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.excludeObjects = objects
+		return nil
+	}
+}
+
+func withAPIKey(ctx context.Context, key string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.ctx = ctx
+		m.clientOptions = append(m.clientOptions, option.WithAPIKey(key))
+		return nil
+	}
+}
+
+func withJSONServiceAccount(ctx context.Context, json []byte) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.ctx = ctx
+		m.clientOptions = append(m.clientOptions, option.WithCredentialsJSON(json))
+		return nil
+	}
+}
+
+func withDefaultADC(ctx context.Context) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.ctx = ctx
+		return nil
+	}
+}
+
+func withoutAuthentication(ctx context.Context) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.ctx = ctx
+		m.clientOptions = append(m.clientOptions, option.WithoutAuthentication())
+		return nil
+	}
+}
+
+// withHTTPClient authenticates the storage client with a pre-built *http.Client instead of a
+// static credential.
+func withHTTPClient(ctx context.Context, client *http.Client) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.ctx = ctx
+		m.clientOptions = append(m.clientOptions, option.WithHTTPClient(client))
+		return nil
+	}
+}
+
+// object is a single GCS object queued for scanning. It's also the io.Reader diskbufferreader
+// wraps for the small-object path, which reads it through the same resumable, retrying
+// ranged reads streamObject uses for large objects so a transient GCS error doesn't abort
+// the whole object.
+type object struct {
+	bucket      string
+	name        string
+	link        string
+	owner       string
+	contentType string
+	acl         []string
+	createdAt   time.Time
+	updatedAt   time.Time
+	size        int64
+	crc32c      uint32
+	generation  int64
+
+	ctx    context.Context
+	handle *storage.ObjectHandle
+
+	offset     int64
+	maxRetries int
+	budget     *retryBudget
+	onRetry    func(retries int)
+}
+
+// Read satisfies io.Reader for diskbufferreader.New, pulling the object through readRangeWithRetry
+// one buffer-sized window at a time rather than opening a single full-object reader that a lone
+// transient error would invalidate.
+func (o *object) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+
+	if o.budget == nil {
+		o.budget = newRetryBudget(o.maxRetries)
+	}
+
+	length := int64(len(p))
+	if o.offset+length > o.size {
+		length = o.size - o.offset
+	}
+
+	buf, retries, err := readRangeWithRetry(o.ctx, o, o.offset, length, o.budget)
+	if retries > 0 && o.onRetry != nil {
+		o.onRetry(retries)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading %q: %w", o.name, err)
+	}
+	if len(buf) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, buf)
+	o.offset += int64(n)
+	return n, nil
+}
+
+// newRangeReader satisfies rangeReader, letting streamObject and Read fetch the object one
+// byte range at a time instead of through a single full-object reader.
+func (o object) newRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return o.handle.Generation(o.generation).NewRangeReader(ctx, offset, length)
+}
+
+func attrsToObject(ctx context.Context, bucket string, attrs *storage.ObjectAttrs, handle *storage.ObjectHandle) object {
+	acl := make([]string, 0, len(attrs.ACL))
+	for _, rule := range attrs.ACL {
+		acl = append(acl, fmt.Sprintf("%s:%s", rule.Entity, rule.Role))
+	}
+
+	return object{
+		bucket:      bucket,
+		name:        attrs.Name,
+		link:        fmt.Sprintf("https://storage.cloud.google.com/%s/%s", bucket, attrs.Name),
+		owner:       attrs.Owner,
+		contentType: attrs.ContentType,
+		acl:         acl,
+		createdAt:   attrs.Created,
+		updatedAt:   attrs.Updated,
+		size:        attrs.Size,
+		crc32c:      attrs.CRC32C,
+		generation:  attrs.Generation,
+		ctx:         ctx,
+		handle:      handle,
+	}
+}
+
+// attributes summarizes the objects a gcsManager is about to scan, used for progress
+// reporting. It's computed once, before any object is read, so there's no per-object retry
+// count to report here yet; retries are instead tallied cumulatively on Source.retryCount as
+// objects are processed and logged in Source.completeProgress.
+type attributes struct {
+	numObjects int64
+	numBuckets int64
+}
+
+// attributes enumerates the configured bucket(s) to compute progress totals. It's a second
+// full listing pass, separate from listObjects, purely for the object/bucket counts.
+func (m *gcsManager) attributes(ctx context.Context) (*attributes, error) {
+	stats := &attributes{}
+
+	buckets, err := m.bucketNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bucketName := range buckets {
+		stats.numBuckets++
+		it := m.client.Bucket(bucketName).Objects(ctx, nil)
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error listing objects in bucket %q: %w", bucketName, err)
+			}
+			if !m.includeObject(attrs.Name) {
+				continue
+			}
+			stats.numObjects++
+		}
+	}
+
+	return stats, nil
+}
+
+// listObjects streams every object in the configured bucket(s) that passes the configured
+// include/exclude filters and the max-object-size limit.
+func (m *gcsManager) listObjects(ctx context.Context) (chan interface{}, error) {
+	buckets, err := m.bucketNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+
+		for _, bucketName := range buckets {
+			bucket := m.client.Bucket(bucketName)
+			it := bucket.Objects(ctx, nil)
+			for {
+				attrs, err := it.Next()
+				if err == iterator.Done {
+					break
+				}
+				if err != nil {
+					ctx.Logger().Error(err, "error listing objects in bucket", "bucket", bucketName)
+					return
+				}
+				if !m.includeObject(attrs.Name) {
+					continue
+				}
+				if m.maxObjectSize > 0 && attrs.Size > m.maxObjectSize {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- attrsToObject(ctx, bucketName, attrs, bucket.Object(attrs.Name)):
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// getObject resolves a single bucket/object pair on demand, used by the Pub/Sub notification
+// listener where each message names exactly one object rather than a bulk listing.
+func (m *gcsManager) getObject(ctx context.Context, bucketName, name string) (object, error) {
+	handle := m.client.Bucket(bucketName).Object(name)
+	attrs, err := handle.Attrs(ctx)
+	if err != nil {
+		return object{}, fmt.Errorf("error fetching attributes for %q/%q: %w", bucketName, name, err)
+	}
+
+	return attrsToObject(ctx, bucketName, attrs, handle), nil
+}
+
+func (m *gcsManager) bucketNames(ctx context.Context) ([]string, error) {
+	if len(m.includeBuckets) > 0 {
+		return m.includeBuckets, nil
+	}
+
+	var names []string
+	it := m.client.Buckets(ctx, m.projectID)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing buckets: %w", err)
+		}
+		if contains(m.excludeBuckets, attrs.Name) {
+			continue
+		}
+		names = append(names, attrs.Name)
+	}
+
+	return names, nil
+}
+
+func (m *gcsManager) includeObject(name string) bool {
+	if len(m.includeObjects) > 0 {
+		return contains(m.includeObjects, name)
+	}
+	if len(m.excludeObjects) > 0 {
+		return !contains(m.excludeObjects, name)
+	}
+	return true
+}
+
+func contains(list []string, want string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package gcs
+
+import (
+	stdctx "context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// objectGetter is satisfied by gcsManager implementations that can resolve a single
+// bucket/object pair on demand, as opposed to listObjects' bulk enumeration. It's used by
+// the Pub/Sub notification listener, where each message names exactly one object.
+type objectGetter interface {
+	getObject(ctx context.Context, bucket, name string) (object, error)
+}
+
+// listenPubSub subscribes to the configured Cloud Pub/Sub subscription and processes GCS
+// object change notifications (OBJECT_FINALIZE, OBJECT_METADATA_UPDATE) as they arrive. It
+// blocks until ctx is cancelled or the subscription is unrecoverably broken. persistableCache
+// is shared with any prior scanOnce sweep (HYBRID mode) so in-memory dedup state survives the
+// handoff instead of being discarded along with a freshly built cache.
+func (s *Source) listenPubSub(ctx context.Context, persistableCache *persistableCache) error {
+	getter, ok := s.gcsManager.(objectGetter)
+	if !ok {
+		return fmt.Errorf("GCS manager does not support fetching individual objects, cannot run in continuous mode")
+	}
+
+	subCfg := s.conn.GetSubscription()
+	if subCfg == nil {
+		return fmt.Errorf("GCS notification_mode requires a subscription to be configured")
+	}
+
+	client, err := pubsub.NewClient(ctx, subCfg.GetProjectId())
+	if err != nil {
+		return fmt.Errorf("error creating pubsub client: %w", err)
+	}
+	defer client.Close()
+
+	sub := client.Subscription(subCfg.GetSubscriptionId())
+	s.Progress.Message = "listening for GCS object notifications..."
+
+	// sub.Receive's callback type is fixed to stdlib context.Context, not this package's
+	// context.Context, so we can't thread a converted msgCtx through the business logic below
+	// without a conversion helper this package doesn't have. We ignore it and reuse the outer,
+	// already-logger-equipped ctx instead; Receive still manages per-message ack-deadline
+	// extension via msgCtx internally regardless of what the callback body does with it.
+	return sub.Receive(ctx, func(_ stdctx.Context, msg *pubsub.Message) {
+		bucket := msg.Attributes["bucketId"]
+		name := msg.Attributes["objectId"]
+		if bucket == "" || name == "" {
+			ctx.Logger().V(1).Info("ignoring pubsub message missing bucketId/objectId attributes")
+			msg.Ack()
+			return
+		}
+
+		if persistableCache.Exists(name) {
+			ctx.Logger().V(5).Info("skipping object, object already processed", "name", name)
+			msg.Ack()
+			return
+		}
+
+		o, err := getter.getObject(ctx, bucket, name)
+		if err != nil {
+			ctx.Logger().Error(err, "error fetching object for pubsub notification", "bucket", bucket, "name", name)
+			msg.Nack()
+			return
+		}
+
+		if err := s.processObject(ctx, o); err != nil {
+			ctx.Logger().V(1).Info("error processing object from pubsub notification", "name", o.name, "error", err)
+			msg.Nack()
+			return
+		}
+
+		s.setProgress(ctx, o.name, persistableCache)
+		msg.Ack()
+	})
+}
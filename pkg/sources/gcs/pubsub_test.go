@@ -0,0 +1,37 @@
+package gcs
+
+import (
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// fakeObjectManager implements objectManager but, on its own, not objectGetter - used to
+// verify listenPubSub's pre-flight checks run (and fail) before any network access.
+type fakeObjectManager struct{}
+
+func (fakeObjectManager) listObjects(_ context.Context) (chan interface{}, error) { return nil, nil }
+func (fakeObjectManager) attributes(_ context.Context) (*attributes, error)       { return nil, nil }
+
+func TestListenPubSubRequiresObjectGetter(t *testing.T) {
+	s := &Source{gcsManager: fakeObjectManager{}, conn: &sourcespb.GCS{}}
+
+	if err := s.listenPubSub(context.Background(), s.setupCache(context.Background())); err == nil {
+		t.Fatal("expected an error when the configured gcsManager doesn't support fetching individual objects")
+	}
+}
+
+type fakeObjectGetterManager struct{ fakeObjectManager }
+
+func (fakeObjectGetterManager) getObject(_ context.Context, _, _ string) (object, error) {
+	return object{}, nil
+}
+
+func TestListenPubSubRequiresSubscription(t *testing.T) {
+	s := &Source{gcsManager: fakeObjectGetterManager{}, conn: &sourcespb.GCS{}}
+
+	if err := s.listenPubSub(context.Background(), s.setupCache(context.Background())); err == nil {
+		t.Fatal("expected an error when notification_mode is set without a subscription configured")
+	}
+}
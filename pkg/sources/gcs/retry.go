@@ -0,0 +1,113 @@
+package gcs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// defaultMaxRetriesPerObject caps retries when the connection doesn't configure one.
+	defaultMaxRetriesPerObject = 5
+
+	retryBackoffInitial    = 1 * time.Second
+	retryBackoffMax        = 30 * time.Second
+	retryBackoffMultiplier = 1.5
+)
+
+// retryBudget tracks the retries spent against a single object, shared across every
+// chunk-sized window streamObject reads so MaxRetriesPerObject bounds the whole object's
+// retries rather than resetting for each window.
+type retryBudget struct {
+	max  int
+	used int
+}
+
+func newRetryBudget(max int) *retryBudget {
+	if max <= 0 {
+		max = defaultMaxRetriesPerObject
+	}
+	return &retryBudget{max: max}
+}
+
+// readRangeWithRetry reads [offset, offset+length) from rr, reopening the range reader at the
+// last successfully read byte on a transient GCS error (5xx, io.ErrUnexpectedEOF) instead of
+// letting one flaky read abort the whole object. It gives up once budget is exhausted and
+// returns the number of retries it spent alongside whatever bytes it did manage to read.
+func readRangeWithRetry(ctx context.Context, rr rangeReader, offset, length int64, budget *retryBudget) ([]byte, int, error) {
+	backoff := gax.Backoff{
+		Initial:    retryBackoffInitial,
+		Max:        retryBackoffMax,
+		Multiplier: retryBackoffMultiplier,
+	}
+
+	buf := make([]byte, 0, length)
+	var read int64
+	spent := 0
+	for {
+		r, err := rr.newRangeReader(ctx, offset+read, length-read)
+		if err == nil {
+			var n int64
+			n, err = copyInto(&buf, r)
+			read += n
+			if closeErr := r.Close(); err == nil && read < length {
+				// Only let a Close error fail the read when bytes are still outstanding;
+				// once every requested byte has been read, a late Close error has nothing
+				// left to invalidate.
+				err = closeErr
+			}
+		}
+
+		if err == nil {
+			return buf, spent, nil
+		}
+
+		if !isRetryableGCSError(err) || budget.used >= budget.max {
+			return buf, spent, fmt.Errorf("error reading range at offset %d: %w", offset+read, err)
+		}
+
+		budget.used++
+		spent++
+		ctx.Logger().V(2).Info("retrying GCS range read after transient error", "offset", offset+read, "attempt", budget.used, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return buf, spent, ctx.Err()
+		case <-time.After(backoff.Pause()):
+		}
+	}
+}
+
+func copyInto(buf *[]byte, r io.Reader) (int64, error) {
+	n, err := io.Copy(sliceWriter{buf}, r)
+	return n, err
+}
+
+// isRetryableGCSError reports whether err looks like a transient condition worth reopening
+// the range reader for, per the GCS retry guidance: 5xx responses and truncated reads.
+func isRetryableGCSError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) {
+		return gErr.Code >= 500 && gErr.Code < 600
+	}
+	return false
+}
+
+// sliceWriter adapts a *[]byte to io.Writer so io.Copy can accumulate a ranged read.
+type sliceWriter struct {
+	buf *[]byte
+}
+
+func (w sliceWriter) Write(p []byte) (int, error) {
+	*w.buf = append(*w.buf, p...)
+	return len(p), nil
+}
@@ -0,0 +1,101 @@
+package gcs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// flakyRangeReader fails its first failsLeft calls to newRangeReader with failErr, then serves
+// the requested range from data.
+type flakyRangeReader struct {
+	data      []byte
+	failsLeft int
+	failErr   error
+}
+
+func (f *flakyRangeReader) newRangeReader(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+	if f.failsLeft > 0 {
+		f.failsLeft--
+		return nil, f.failErr
+	}
+	end := offset + length
+	if end > int64(len(f.data)) {
+		end = int64(len(f.data))
+	}
+	return io.NopCloser(bytes.NewReader(f.data[offset:end])), nil
+}
+
+func TestReadRangeWithRetrySucceedsAfterTransientError(t *testing.T) {
+	data := []byte("hello world")
+	rr := &flakyRangeReader{data: data, failsLeft: 1, failErr: &googleapi.Error{Code: 503}}
+	budget := newRetryBudget(2)
+
+	buf, retries, err := readRangeWithRetry(context.Background(), rr, 0, int64(len(data)), budget)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if retries != 1 {
+		t.Errorf("retries = %d, want 1", retries)
+	}
+	if !bytes.Equal(buf, data) {
+		t.Errorf("buf = %q, want %q", buf, data)
+	}
+	if budget.used != 1 {
+		t.Errorf("budget.used = %d, want 1", budget.used)
+	}
+}
+
+func TestReadRangeWithRetryExhaustsBudget(t *testing.T) {
+	rr := &flakyRangeReader{data: []byte("hello world"), failsLeft: 5, failErr: &googleapi.Error{Code: 503}}
+	budget := newRetryBudget(1)
+
+	_, retries, err := readRangeWithRetry(context.Background(), rr, 0, 5, budget)
+	if err == nil {
+		t.Fatal("expected an error once the retry budget is exhausted, got nil")
+	}
+	if retries != 1 {
+		t.Errorf("retries = %d, want 1", retries)
+	}
+	if budget.used != budget.max {
+		t.Errorf("budget.used = %d, want %d (exhausted)", budget.used, budget.max)
+	}
+}
+
+func TestReadRangeWithRetryNonRetryableErrorFailsImmediately(t *testing.T) {
+	rr := &flakyRangeReader{data: []byte("hello world"), failsLeft: 1, failErr: &googleapi.Error{Code: 404}}
+	budget := newRetryBudget(5)
+
+	_, retries, err := readRangeWithRetry(context.Background(), rr, 0, 5, budget)
+	if err == nil {
+		t.Fatal("expected a non-retryable error to be returned immediately, got nil")
+	}
+	if retries != 0 {
+		t.Errorf("retries = %d, want 0 for a non-retryable error", retries)
+	}
+}
+
+func TestIsRetryableGCSError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"5xx googleapi error", &googleapi.Error{Code: 503}, true},
+		{"4xx googleapi error", &googleapi.Error{Code: 404}, false},
+		{"unrelated error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableGCSError(tt.err); got != tt.want {
+				t.Errorf("isRetryableGCSError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package gcs
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultChunkSize is the size of each ranged read performed by streamObject when the
+	// connection doesn't configure one explicitly.
+	defaultChunkSize = 10 * 1024 * 1024 // 10 MiB
+
+	// chunkOverlapSize is prepended from the tail of the previous range onto the next one so
+	// secrets that straddle a chunk boundary are still detected whole.
+	chunkOverlapSize = 512
+
+	// defaultSmallObjectThreshold is the largest object size that's still read in full and
+	// handed to handlers.HandleFile. Anything larger goes through the ranged streaming path.
+	defaultSmallObjectThreshold = 50 * 1024 * 1024 // 50 MiB
+)
+
+// rangeReader is implemented by objects that can serve a ranged read directly from the
+// backing storage.ObjectHandle. It's the extension point streamObject uses to fetch one
+// chunk-sized window at a time instead of reading the whole object into memory.
+type rangeReader interface {
+	newRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+}
+
+// streamObject reads o in fixed-size, overlapping windows and emits one *sources.Chunk per
+// window directly onto s.chunksCh, rather than buffering the whole object as readObjectData
+// does for small objects. It verifies the object's stored CRC32C incrementally against the
+// data actually read and aborts with an error on mismatch.
+func (s *Source) streamObject(ctx context.Context, o object, chunkSkel *sources.Chunk) error {
+	rr, ok := any(o).(rangeReader)
+	if !ok {
+		return fmt.Errorf("object %q does not support ranged reads, cannot stream", o.name)
+	}
+	return s.streamRanges(ctx, rr, o.bucket, o.name, o.size, o.crc32c, chunkSkel)
+}
+
+// streamRanges is streamObject's core window/overlap/checksum loop, pulled out so it can run
+// against a fake rangeReader in tests instead of a live storage.ObjectHandle.
+func (s *Source) streamRanges(ctx context.Context, rr rangeReader, bucket, name string, size int64, wantCRC32C uint32, chunkSkel *sources.Chunk) error {
+	chunkSize := s.chunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	hasher := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	budget := newRetryBudget(s.maxRetriesPerObject)
+	var overlap []byte
+	var offset int64
+	for offset < size {
+		length := chunkSize
+		if offset+length > size {
+			length = size - offset
+		}
+
+		buf, retries, err := readRangeWithRetry(ctx, rr, offset, length, budget)
+		if retries > 0 {
+			s.mu.Lock()
+			s.retryCount += retries
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %q at offset %d: %w", name, offset, err)
+		}
+		if len(buf) == 0 {
+			break
+		}
+
+		if _, err := hasher.Write(buf); err != nil {
+			return fmt.Errorf("error updating checksum for %q: %w", name, err)
+		}
+
+		if err := s.emitChunk(ctx, chunkSkel, offset-int64(len(overlap)), append(overlap, buf...)); err != nil {
+			return err
+		}
+
+		if len(buf) > chunkOverlapSize {
+			overlap = append([]byte(nil), buf[len(buf)-chunkOverlapSize:]...)
+		} else {
+			overlap = append([]byte(nil), buf...)
+		}
+		offset += int64(len(buf))
+	}
+
+	if sum := hasher.Sum32(); sum != wantCRC32C {
+		err := fmt.Errorf("CRC32C mismatch for %q: got %d, want %d", name, sum, wantCRC32C)
+		ctx.Logger().Error(err, "object failed checksum verification, aborting", "bucket", bucket, "name", name)
+		return err
+	}
+
+	return nil
+}
+
+// emitChunk builds a chunk from chunkSkel, tagging it with its offset in the source object,
+// and sends it on s.chunksCh.
+func (s *Source) emitChunk(ctx context.Context, chunkSkel *sources.Chunk, offset int64, data []byte) error {
+	gcsMeta := *chunkSkel.SourceMetadata.GetGcs()
+	gcsMeta.ChunkOffset = offset
+
+	c := *chunkSkel
+	c.SourceMetadata = &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Gcs{Gcs: &gcsMeta},
+	}
+	c.Data = data
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.chunksCh <- &c:
+	}
+	return nil
+}
+
+// withChunkSize sets the window size streamObject uses for ranged reads against large
+// objects.
+func withChunkSize(size int64) gcsManagerOption {
+	return func(manager *gcsManager) error {
+		manager.chunkSize = size
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package gcs
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeRangeReader serves ranged reads directly from an in-memory buffer, standing in for a
+// live storage.ObjectHandle-backed object in tests.
+type fakeRangeReader struct {
+	data []byte
+}
+
+func (f *fakeRangeReader) newRangeReader(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+	if offset >= int64(len(f.data)) {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	end := offset + length
+	if end > int64(len(f.data)) {
+		end = int64(len(f.data))
+	}
+	return io.NopCloser(bytes.NewReader(f.data[offset:end])), nil
+}
+
+func testChunkSkel() *sources.Chunk {
+	return &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Gcs{Gcs: &source_metadatapb.GCS{}},
+		},
+	}
+}
+
+func castagnoliSum(data []byte) uint32 {
+	return crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+}
+
+// TestStreamRangesChunkOffsets verifies each emitted chunk's ChunkOffset points at where its
+// data (including any prepended overlap) actually starts in the object, not at the start of
+// the newly-read range.
+func TestStreamRangesChunkOffsets(t *testing.T) {
+	data := []byte("abcdefghijkl")
+	rr := &fakeRangeReader{data: data}
+
+	s := &Source{chunkSize: 4, chunksCh: make(chan *sources.Chunk, 16)}
+	if err := s.streamRanges(context.Background(), rr, "bucket", "name", int64(len(data)), castagnoliSum(data), testChunkSkel()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	close(s.chunksCh)
+
+	var gotChunks int
+	for c := range s.chunksCh {
+		gotChunks++
+		offset := c.SourceMetadata.GetGcs().ChunkOffset
+		if offset < 0 || offset+int64(len(c.Data)) > int64(len(data)) {
+			t.Fatalf("chunk offset %d with length %d is out of bounds for a %d-byte object", offset, len(c.Data), len(data))
+		}
+		if !bytes.Equal(c.Data, data[offset:offset+int64(len(c.Data))]) {
+			t.Errorf("chunk at offset %d does not match the object's bytes at that offset", offset)
+		}
+	}
+	if gotChunks == 0 {
+		t.Fatal("expected at least one chunk to be emitted")
+	}
+}
+
+// TestStreamRangesChecksumMismatch verifies streamRanges aborts with an error once every
+// range has been read but the accumulated CRC32C doesn't match what the object claims.
+func TestStreamRangesChecksumMismatch(t *testing.T) {
+	data := []byte("abcdefghijkl")
+	rr := &fakeRangeReader{data: data}
+
+	s := &Source{chunkSize: 4, chunksCh: make(chan *sources.Chunk, 16)}
+	err := s.streamRanges(context.Background(), rr, "bucket", "name", int64(len(data)), castagnoliSum(data)+1, testChunkSkel())
+	if err == nil {
+		t.Fatal("expected a checksum mismatch error, got nil")
+	}
+}
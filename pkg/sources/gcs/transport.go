@@ -0,0 +1,54 @@
+package gcs
+
+import (
+	"fmt"
+
+	"google.golang.org/api/option"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// defaultGRPCConnPoolSize is used when a caller opts into the gRPC transport without
+// specifying a pool size.
+const defaultGRPCConnPoolSize = 4
+
+// grpcIncompatibleCredentials are the auth types the gRPC transport doesn't support; the
+// storage client falls back to JSON+HTTPS for these regardless of what was requested.
+var grpcIncompatibleCredentials = map[string]bool{
+	"*sourcespb.GCS_Unauthenticated":            true,
+	"*sourcespb.GCS_Oauth":                      true,
+	"*sourcespb.GCS_HmacKey":                    true,
+	"*sourcespb.GCS_WorkloadIdentityFederation": true,
+}
+
+// gcsTransportOption returns the gcsManagerOption that configures the storage client's
+// transport, or nil to leave the default (JSON) transport in place. gRPC is only selected
+// when explicitly requested and the configured auth type supports it.
+func gcsTransportOption(aCtx context.Context, conn *sourcespb.GCS) gcsManagerOption {
+	if conn.GetTransport() != sourcespb.GCS_GRPC {
+		return nil
+	}
+
+	credType := fmt.Sprintf("%T", conn.Credential)
+	if grpcIncompatibleCredentials[credType] {
+		aCtx.Logger().V(1).Info("GCS gRPC transport isn't supported with this credential type, falling back to JSON", "credential", credType)
+		return nil
+	}
+
+	poolSize := int(conn.GetGrpcConnPoolSize())
+	if poolSize <= 0 {
+		poolSize = defaultGRPCConnPoolSize
+	}
+	return withGRPCTransport(poolSize)
+}
+
+// withGRPCTransport configures the gcsManager's storage client to use the gRPC transport,
+// multiplexed over a connection pool of the given size, instead of JSON+HTTPS.
+func withGRPCTransport(poolSize int) gcsManagerOption {
+	return func(manager *gcsManager) error {
+		manager.useGRPC = true
+		manager.clientOptions = append(manager.clientOptions, option.WithGRPCConnectionPool(poolSize))
+		return nil
+	}
+}
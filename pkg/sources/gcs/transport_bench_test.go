@@ -0,0 +1,71 @@
+package gcs
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// benchFixtureBucketEnv names the environment variable that points at a GCS bucket used to
+// compare transport throughput. Both benchmarks are skipped when it's unset, since they
+// require real network access and a populated bucket.
+const benchFixtureBucketEnv = "GCS_BENCH_FIXTURE_BUCKET"
+
+func benchmarkTransport(b *testing.B, transport sourcespb.GCS_Transport) {
+	bucket := os.Getenv(benchFixtureBucketEnv)
+	if bucket == "" {
+		b.Skipf("%s not set, skipping transport benchmark", benchFixtureBucketEnv)
+	}
+
+	ctx := context.Background()
+	conn := &sourcespb.GCS{
+		ProjectId:      bucket,
+		Credential:     &sourcespb.GCS_Adc{Adc: true},
+		Transport:      transport,
+		IncludeBuckets: []string{bucket},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gcsManager, err := configureGCSManager(ctx, conn, 8)
+		if err != nil {
+			b.Fatalf("error configuring GCS manager: %s", err)
+		}
+
+		objectCh, err := gcsManager.listObjects(ctx)
+		if err != nil {
+			b.Fatalf("error listing objects: %s", err)
+		}
+
+		var bytesRead int64
+		for obj := range objectCh {
+			o, ok := obj.(object)
+			if !ok {
+				continue
+			}
+
+			r, err := o.newRangeReader(ctx, 0, -1)
+			if err != nil {
+				b.Fatalf("error opening reader for %q: %s", o.name, err)
+			}
+			n, err := io.Copy(io.Discard, r)
+			r.Close()
+			if err != nil {
+				b.Fatalf("error reading %q: %s", o.name, err)
+			}
+			bytesRead += n
+		}
+		b.SetBytes(bytesRead)
+	}
+}
+
+func BenchmarkJSONTransport(b *testing.B) {
+	benchmarkTransport(b, sourcespb.GCS_JSON)
+}
+
+func BenchmarkGRPCTransport(b *testing.B) {
+	benchmarkTransport(b, sourcespb.GCS_GRPC)
+}
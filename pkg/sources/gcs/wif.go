@@ -0,0 +1,112 @@
+package gcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+)
+
+// workloadIdentityFederationClient exchanges an external OIDC/AWS/Azure token for a
+// short-lived Google access token via sts.googleapis.com, returning an *http.Client that
+// refreshes the exchanged token as it nears expiry. Google's external-account
+// implementation (golang.org/x/oauth2/google) already handles the 1-hour token lifetime,
+// so long-running scans keep working without any extra refresh logic here.
+func workloadIdentityFederationClient(ctx context.Context, creds *credentialspb.WorkloadIdentityFederation) (*http.Client, error) {
+	if creds == nil {
+		return nil, fmt.Errorf("workload identity federation credentials are required")
+	}
+	if creds.GetAudience() == "" || creds.GetSubjectTokenType() == "" || creds.GetTokenUrl() == "" {
+		return nil, fmt.Errorf("workload identity federation credentials are incomplete, audience, subject_token_type, and token_url are required")
+	}
+
+	cfg := google.CredentialsParams{
+		Scopes: []string{storageReadOnlyScope},
+	}
+
+	extCfg := externalAccountConfig{
+		Audience:         creds.GetAudience(),
+		SubjectTokenType: creds.GetSubjectTokenType(),
+		TokenURL:         creds.GetTokenUrl(),
+	}
+
+	switch {
+	case creds.GetSubjectToken() != "":
+		tokenFile, err := inlineSubjectTokenFile(creds.GetSubjectToken())
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			if err := os.Remove(tokenFile); err != nil && !os.IsNotExist(err) {
+				ctx.Logger().V(1).Info("error removing inline WIF subject token file", "error", err)
+			}
+		}()
+		extCfg.CredentialSource = &credentialSourceFile{File: tokenFile}
+	case creds.GetCredentialSourceFile() != "":
+		extCfg.CredentialSource = &credentialSourceFile{File: creds.GetCredentialSourceFile()}
+	default:
+		return nil, fmt.Errorf("workload identity federation credentials require either subject_token or credential_source to be set")
+	}
+
+	jsonCfg, err := json.Marshal(extCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling external account config: %w", err)
+	}
+
+	wifCreds, err := google.CredentialsFromJSONWithParams(ctx, jsonCfg, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building external account credentials: %w", err)
+	}
+
+	return oauth2.NewClient(ctx, wifCreds.TokenSource), nil
+}
+
+// storageReadOnlyScope mirrors storage.ScopeReadOnly without importing cloud.google.com/go/storage
+// here, keeping this file focused on the token exchange.
+const storageReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// externalAccountConfig is the subset of Google's external_account credential file format
+// needed to exchange a subject token for a Google access token.
+type externalAccountConfig struct {
+	Type             string                `json:"type"`
+	Audience         string                `json:"audience"`
+	SubjectTokenType string                `json:"subject_token_type"`
+	TokenURL         string                `json:"token_url"`
+	CredentialSource *credentialSourceFile `json:"credential_source,omitempty"`
+}
+
+type credentialSourceFile struct {
+	File string `json:"file"`
+}
+
+func (c externalAccountConfig) MarshalJSON() ([]byte, error) {
+	type alias externalAccountConfig
+	a := alias(c)
+	a.Type = "external_account"
+	return json.Marshal(a)
+}
+
+// inlineSubjectTokenFile writes an inline subject token to a temp file, since Google's
+// external_account format only reads subject tokens from disk or a URL/executable source.
+// The caller owns the returned path and is responsible for removing it once the token is no
+// longer needed; workloadIdentityFederationClient ties its lifetime to ctx.
+func inlineSubjectTokenFile(token string) (string, error) {
+	f, err := os.CreateTemp("", "gcs-wif-subject-token-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating subject token file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(token); err != nil {
+		return "", fmt.Errorf("error writing subject token file: %w", err)
+	}
+
+	return f.Name(), nil
+}